@@ -13,6 +13,8 @@ import (
 	"github.com/micro/go-micro/v3/broker/http"
 	"github.com/micro/go-micro/v3/client"
 	config "github.com/micro/go-micro/v3/config/store"
+	"github.com/micro/go-micro/v3/events/stream/jetstream"
+	"github.com/micro/go-micro/v3/events/stream/kafka"
 	memStream "github.com/micro/go-micro/v3/events/stream/memory"
 	"github.com/micro/go-micro/v3/registry"
 	"github.com/micro/go-micro/v3/registry/mdns"
@@ -38,6 +40,12 @@ import (
 	microRegistry "github.com/micro/micro/v3/service/registry"
 	microRouter "github.com/micro/micro/v3/service/router"
 	microRuntime "github.com/micro/micro/v3/service/runtime"
+	"github.com/micro/micro/v3/service/runtime/builder"
+	"github.com/micro/micro/v3/service/runtime/builder/buildpacks"
+	"github.com/micro/micro/v3/service/runtime/builder/docker"
+	"github.com/micro/micro/v3/service/runtime/builder/kaniko"
+	"github.com/micro/micro/v3/service/runtime/gitops"
+	"github.com/micro/micro/v3/service/runtime/nomad"
 	microServer "github.com/micro/micro/v3/service/server"
 	microStore "github.com/micro/micro/v3/service/store"
 )
@@ -50,6 +58,9 @@ var profiles = map[string]*Profile{
 	"test":       Test,
 	"local":      Local,
 	"kubernetes": Kubernetes,
+	"nomad":      Nomad,
+	"gitops":     GitOps,
+	"production": Production,
 }
 
 // Profile configures an environment
@@ -132,6 +143,91 @@ var Kubernetes = &Profile{
 		microAuth.DefaultAuth = jwt.NewAuth()
 		SetupJWT(ctx)
 		SetupConfigSecretKey(ctx)
+		SetupBuilder(ctx)
+
+		return nil
+	},
+}
+
+// Nomad profile to run on a HashiCorp Nomad cluster
+var Nomad = &Profile{
+	Name: "nomad",
+	Setup: func(ctx *cli.Context) error {
+		// using a static router so queries are routed based on service name
+		microRouter.DefaultRouter = static.NewRouter()
+		// the nomad runtime manages services as Nomad jobs, reconciling
+		// against the cluster's allocation list rather than a single host
+		microRuntime.DefaultRuntime = nomad.NewRuntime()
+		microAuth.DefaultAuth = jwt.NewAuth()
+		SetupJWT(ctx)
+		SetupConfigSecretKey(ctx)
+		SetupBuilder(ctx)
+
+		return nil
+	},
+}
+
+// GitOps profile syncs services declaratively from a Git repository instead
+// of accepting manual Create/Update/Delete calls. It runs on top of the
+// kubernetes runtime; the repo to sync is configured via `micro gitops add`.
+var GitOps = &Profile{
+	Name: "gitops",
+	Setup: func(ctx *cli.Context) error {
+		microRouter.DefaultRouter = static.NewRouter()
+		microRuntime.DefaultRuntime = kubernetes.NewRuntime()
+		microAuth.DefaultAuth = jwt.NewAuth()
+		SetupJWT(ctx)
+		SetupConfigSecretKey(ctx)
+		SetupBuilder(ctx)
+
+		if repo := os.Getenv("MICRO_GITOPS_REPO"); len(repo) > 0 {
+			ns := os.Getenv("MICRO_GITOPS_NAMESPACE")
+			if len(ns) == 0 {
+				ns = "default"
+			}
+			gitops.DefaultManager.AddRepo(repo, ns, "/tmp/micro-gitops-"+ns)
+		}
+
+		// pick up any repos registered via `micro gitops add` against a
+		// previous run of this server
+		regs, err := gitops.LoadRepos()
+		if err != nil {
+			logger.Warnf("Error loading persisted gitops repos: %v", err)
+		}
+		for _, reg := range regs {
+			gitops.DefaultManager.AddRepo(reg.Repo, reg.Namespace, reg.CheckoutDir)
+		}
+
+		return nil
+	},
+}
+
+// Production profile runs on the kubernetes runtime with a durable events
+// stream, so an in-flight build/deploy can be resumed after the manager
+// restarts instead of being silently lost like it would be with the
+// in-memory stream the Local and Test profiles use.
+var Production = &Profile{
+	Name: "production",
+	Setup: func(ctx *cli.Context) error {
+		microAuth.DefaultAuth = jwt.NewAuth()
+		SetupConfigSecretKey(ctx)
+		microConfig.DefaultConfig, _ = config.NewConfig(microStore.DefaultStore, "")
+		SetupRegistry(mdns.NewRegistry())
+		SetupJWT(ctx)
+
+		microRuntime.DefaultRuntime = kubernetes.NewRuntime()
+		SetupBuilder(ctx)
+
+		var err error
+		switch os.Getenv("MICRO_EVENTS_STREAM") {
+		case "kafka":
+			microEvents.DefaultStream, err = kafka.NewStream()
+		default:
+			microEvents.DefaultStream, err = jetstream.NewStream()
+		}
+		if err != nil {
+			logger.Fatalf("Error configuring stream: %v", err)
+		}
 
 		return nil
 	},
@@ -195,6 +291,31 @@ func SetupJWT(ctx *cli.Context) {
 
 }
 
+// SetupBuilder registers the buildpacks, kaniko and docker builders and
+// selects one as builder.DefaultBuilder via the --builder flag (or
+// MICRO_BUILDER), defaulting to buildpacks since it needs neither a
+// Dockerfile nor a Docker daemon. Profiles whose runtime runs source
+// directly (e.g. Local) must not call this.
+func SetupBuilder(ctx *cli.Context) {
+	builder.Register("buildpacks", buildpacks.NewBuilder())
+	builder.Register("kaniko", kaniko.NewBuilder())
+	builder.Register("docker", docker.NewBuilder())
+
+	name := ctx.String("builder")
+	if len(name) == 0 {
+		name = os.Getenv("MICRO_BUILDER")
+	}
+	if len(name) == 0 {
+		name = "buildpacks"
+	}
+
+	b, err := builder.Get(name)
+	if err != nil {
+		logger.Fatalf("Error configuring builder: %v", err)
+	}
+	builder.DefaultBuilder = b
+}
+
 func SetupConfigSecretKey(ctx *cli.Context) {
 	key := ctx.String("config_secret_key")
 	if len(key) == 0 {
@@ -0,0 +1,140 @@
+// Package builder produces an OCI image from a checked out source tree and
+// pushes it to a registry. The manager selects an implementation per
+// service via runtime.CreateOptions.Builder, falling back to DefaultBuilder
+// when none is specified.
+package builder
+
+import "fmt"
+
+// Status is a stage in the build pipeline. The manager does not write these
+// values to a service; while a build runs it records gorun.Building.
+type Status string
+
+const (
+	// Fetching the source tree
+	Fetching Status = "fetching"
+	// Building the OCI image
+	Building Status = "building"
+	// Pushing the image to the registry
+	Pushing Status = "pushing"
+	// Deploying the built image into the runtime
+	Deploying Status = "deploying"
+)
+
+// Builder builds a checked out source tree into an OCI image and pushes it
+// to a registry, returning an image reference the runtime can run
+type Builder interface {
+	// Build builds and pushes the image, streaming log lines to onLog as
+	// the build progresses
+	Build(src string, onLog func(line string), opts ...Option) (string, error)
+	// String returns the name of the builder
+	String() string
+}
+
+// DefaultBuilder is used when a service doesn't specify a builder and no
+// namespace default has been configured. It's nil by default: the local
+// runtime runs source directly and doesn't need a builder at all.
+var DefaultBuilder Builder
+
+// Options configure a build
+type Options struct {
+	// Registry to push the built image to, e.g. "registry.example.com/micro"
+	Registry string
+	// Namespace the service belongs to
+	Namespace string
+	// Name of the service being built
+	Name string
+	// Version of the service being built
+	Version string
+}
+
+// Option sets an Options value
+type Option func(*Options)
+
+// Registry sets the registry images are pushed to
+func Registry(r string) Option {
+	return func(o *Options) {
+		o.Registry = r
+	}
+}
+
+// Namespace sets the namespace the build belongs to
+func Namespace(ns string) Option {
+	return func(o *Options) {
+		o.Namespace = ns
+	}
+}
+
+// Name sets the name of the service being built, used to keep each
+// service's image distinct in the registry
+func Name(name string) Option {
+	return func(o *Options) {
+		o.Name = name
+	}
+}
+
+// Version sets the version of the service being built, used as the image
+// tag so a rebuild doesn't overwrite a version still running
+func Version(version string) Option {
+	return func(o *Options) {
+		o.Version = version
+	}
+}
+
+// Ref builds the canonical image reference for these options, in the form
+// registry/namespace/name:version. Builder implementations should use this
+// rather than hand-rolling their own, so every builder produces an image
+// reference that's unique per service per version.
+func Ref(o Options) string {
+	return fmt.Sprintf("%v/%v/%v:%v", o.Registry, o.Namespace, o.Name, o.Version)
+}
+
+// namespaceDefaults maps a namespace to the builder selected for every
+// service created in it that doesn't set CreateOptions.Builder itself.
+// Falls through to DefaultBuilder when a namespace has none set.
+// TODO: expose a CLI/config surface to populate this; for now it's set
+// directly by whatever wants per-namespace builder selection.
+var namespaceDefaults = map[string]string{}
+
+// SetNamespaceDefault selects name as the builder used for every service in
+// namespace that doesn't specify its own Builder
+func SetNamespaceDefault(namespace, name string) {
+	namespaceDefaults[namespace] = name
+}
+
+// NamespaceDefault returns the builder name configured as the default for
+// namespace, or "" if none is set
+func NamespaceDefault(namespace string) string {
+	return namespaceDefaults[namespace]
+}
+
+// builders is the registry of builders available by name, e.g. "buildpacks",
+// "kaniko", "docker"
+var builders = map[string]Builder{}
+
+// Register makes a builder available by name, selectable per-service via
+// runtime.CreateOptions.Builder or as a namespace default
+func Register(name string, b Builder) error {
+	if _, ok := builders[name]; ok {
+		return fmt.Errorf("builder %s already exists", name)
+	}
+	builders[name] = b
+	return nil
+}
+
+// Get returns the named builder, falling back to DefaultBuilder if name is
+// empty
+func Get(name string) (Builder, error) {
+	if len(name) == 0 {
+		if DefaultBuilder == nil {
+			return nil, fmt.Errorf("no builder configured")
+		}
+		return DefaultBuilder, nil
+	}
+
+	b, ok := builders[name]
+	if !ok {
+		return nil, fmt.Errorf("builder %s does not exist", name)
+	}
+	return b, nil
+}
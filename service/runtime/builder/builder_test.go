@@ -0,0 +1,56 @@
+package builder
+
+import "testing"
+
+func TestRefIncludesNameAndVersion(t *testing.T) {
+	o := Options{Registry: "registry.micro.mu", Namespace: "default", Name: "foo", Version: "v1"}
+	got := Ref(o)
+	want := "registry.micro.mu/default/foo:v1"
+	if got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestRefDistinguishesServices(t *testing.T) {
+	a := Ref(Options{Registry: "registry.micro.mu", Namespace: "default", Name: "foo", Version: "v1"})
+	b := Ref(Options{Registry: "registry.micro.mu", Namespace: "default", Name: "bar", Version: "v1"})
+	if a == b {
+		t.Fatalf("refs for different services should not collide, both got %v", a)
+	}
+}
+
+func TestRefDistinguishesVersions(t *testing.T) {
+	a := Ref(Options{Registry: "registry.micro.mu", Namespace: "default", Name: "foo", Version: "v1"})
+	b := Ref(Options{Registry: "registry.micro.mu", Namespace: "default", Name: "foo", Version: "v2"})
+	if a == b {
+		t.Fatalf("refs for different versions should not collide, both got %v", a)
+	}
+}
+
+func TestGetFallsBackToDefaultBuilder(t *testing.T) {
+	prev := DefaultBuilder
+	defer func() { DefaultBuilder = prev }()
+
+	DefaultBuilder = fakeBuilder("buildpacks")
+	b, err := Get("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != "buildpacks" {
+		t.Fatalf("got %v, want buildpacks", b.String())
+	}
+}
+
+func TestGetUnknownBuilder(t *testing.T) {
+	if _, err := Get("does-not-exist"); err == nil {
+		t.Fatal("expected an error for an unregistered builder, got nil")
+	}
+}
+
+type fakeBuilder string
+
+func (f fakeBuilder) Build(src string, onLog func(string), opts ...Option) (string, error) {
+	return "", nil
+}
+
+func (f fakeBuilder) String() string { return string(f) }
@@ -0,0 +1,64 @@
+// Package buildpacks builds service source using Cloud Native Buildpacks
+// (pack build), producing an OCI image without a Dockerfile.
+package buildpacks
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+
+	"github.com/micro/micro/v3/service/runtime/builder"
+)
+
+// defaultBuilderImage is the buildpacks builder image used when none is
+// configured
+const defaultBuilderImage = "paketobuildpacks/builder:base"
+
+// Builder builds source using Cloud Native Buildpacks via the pack CLI
+type Builder struct {
+	// Image is the buildpacks builder image to use
+	Image string
+}
+
+// NewBuilder returns a buildpacks Builder
+func NewBuilder() *Builder {
+	return &Builder{Image: defaultBuilderImage}
+}
+
+// Build runs `pack build` against src, streaming output to onLog, and
+// returns the resulting image reference
+func (b *Builder) Build(src string, onLog func(line string), opts ...builder.Option) (string, error) {
+	var options builder.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	ref := builder.Ref(options)
+
+	cmd := exec.Command("pack", "build", ref, "--path", src, "--builder", b.Image, "--publish")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		onLog(scanner.Text())
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return "", fmt.Errorf("buildpacks build failed: %w", err)
+	}
+
+	return ref, nil
+}
+
+// String returns the name of the builder
+func (b *Builder) String() string {
+	return "buildpacks"
+}
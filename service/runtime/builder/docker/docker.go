@@ -0,0 +1,64 @@
+// Package docker builds service source into an OCI image using a local
+// Docker daemon, the simplest option for development or single-node setups.
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+
+	"github.com/micro/micro/v3/service/runtime/builder"
+)
+
+// Builder builds source using the local Docker daemon
+type Builder struct{}
+
+// NewBuilder returns a docker Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Build runs `docker build` then `docker push` against src, streaming
+// output to onLog, and returns the resulting image reference
+func (b *Builder) Build(src string, onLog func(line string), opts ...builder.Option) (string, error) {
+	var options builder.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	ref := builder.Ref(options)
+
+	if err := run(onLog, "docker", "build", "-t", ref, src); err != nil {
+		return "", fmt.Errorf("docker build failed: %w", err)
+	}
+	if err := run(onLog, "docker", "push", ref); err != nil {
+		return "", fmt.Errorf("docker push failed: %w", err)
+	}
+
+	return ref, nil
+}
+
+func run(onLog func(line string), name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		onLog(scanner.Text())
+	}
+
+	return cmd.Wait()
+}
+
+// String returns the name of the builder
+func (b *Builder) String() string {
+	return "docker"
+}
@@ -0,0 +1,68 @@
+// Package kaniko builds service source into an OCI image using Kaniko,
+// which builds from a Dockerfile without requiring a Docker daemon. This
+// makes it suitable for running the build itself inside a cluster such as
+// Kubernetes.
+package kaniko
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+
+	"github.com/micro/micro/v3/service/runtime/builder"
+)
+
+// Builder builds source using the kaniko executor
+type Builder struct{}
+
+// NewBuilder returns a kaniko Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Build runs the kaniko executor against src, streaming output to onLog,
+// and returns the resulting image reference. src is expected to contain a
+// Dockerfile; this builder does not generate one.
+func (b *Builder) Build(src string, onLog func(line string), opts ...builder.Option) (string, error) {
+	var options builder.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	ref := builder.Ref(options)
+
+	cmd := exec.Command("executor",
+		"--context", src,
+		"--destination", ref,
+		"--no-push", // overridden below once push is configured
+	)
+	if len(options.Registry) > 0 {
+		cmd.Args = cmd.Args[:len(cmd.Args)-1]
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		onLog(scanner.Text())
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return "", fmt.Errorf("kaniko build failed: %w", err)
+	}
+
+	return ref, nil
+}
+
+// String returns the name of the builder
+func (b *Builder) String() string {
+	return "kaniko"
+}
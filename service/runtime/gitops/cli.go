@@ -0,0 +1,49 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// DefaultManager is used by the server-side gitops profile to run the
+// reconcilers registered via SaveRepo/LoadRepos. It is NOT used by the
+// `micro gitops add` CLI command below: that command runs in its own
+// short-lived process, so starting a reconciler on DefaultManager there
+// would die the instant the CLI exits.
+var DefaultManager = NewManager()
+
+// Commands returns the `micro gitops` CLI commands used to bootstrap a repo
+func Commands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "gitops",
+			Usage: "Manage GitOps repositories synced into this environment",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "add",
+					Usage: "Register a repo of service manifests to sync into a namespace",
+					Flags: []cli.Flag{
+						&cli.StringFlag{Name: "repo", Required: true},
+						&cli.StringFlag{Name: "namespace", Value: "default"},
+					},
+					Action: func(ctx *cli.Context) error {
+						repo := ctx.String("repo")
+						ns := ctx.String("namespace")
+						checkoutDir := "/tmp/micro-gitops-" + ns
+
+						// persist the registration so the long-running server
+						// process (not this CLI invocation) picks it up and
+						// runs the actual reconciler
+						if err := SaveRepo(repo, ns, checkoutDir); err != nil {
+							return fmt.Errorf("error saving repo registration: %w", err)
+						}
+
+						fmt.Printf("Registered %v to sync into namespace %v; it will be picked up by the runtime service\n", repo, ns)
+						return nil
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,262 @@
+// Package gitops replaces manual Create/Update/Delete calls with
+// declarative sync from a Git repository of per-service manifests. A
+// Reconciler polls the repo on an interval (there is no webhook trigger),
+// diffs the desired state against what's running, and calls the existing
+// runtime methods to converge.
+package gitops
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/runtime"
+)
+
+// Manifest is the declarative description of a single service, read from a
+// YAML file in the repo
+type Manifest struct {
+	Name      string             `yaml:"name"`
+	Version   string             `yaml:"version"`
+	Source    string             `yaml:"source"`
+	Env       map[string]string  `yaml:"env"`
+	Instances int                `yaml:"instances"`
+	Autoscale *AutoscaleManifest `yaml:"autoscale"`
+}
+
+// AutoscaleManifest is the YAML form of the min/max/target CPU fields of
+// runtime.AutoscalePolicy; TargetRPS and Cooldown are not read from
+// manifests
+type AutoscaleManifest struct {
+	Min              int `yaml:"min"`
+	Max              int `yaml:"max"`
+	TargetCPUPercent int `yaml:"target_cpu_percent"`
+}
+
+// Reconciler syncs a single namespace from a single repo
+type Reconciler struct {
+	// Repo is the git remote to sync from
+	Repo string
+	// Namespace this repo's manifests are applied to
+	Namespace string
+	// PollInterval between syncs
+	PollInterval time.Duration
+
+	checkoutDir string
+	// stop, once closed, ends this reconciler's Start loop. It's owned by
+	// the Reconciler itself (rather than shared across every reconciler a
+	// Manager runs) so replacing one repo's reconciler can stop only that
+	// one without racing the others.
+	stop chan bool
+}
+
+// NewReconciler returns a Reconciler for the given repo and namespace,
+// checked out beneath dir
+func NewReconciler(repo, namespace, dir string) *Reconciler {
+	return &Reconciler{
+		Repo:         repo,
+		Namespace:    namespace,
+		PollInterval: time.Minute,
+		checkoutDir:  dir,
+		stop:         make(chan bool),
+	}
+}
+
+// Stop ends this reconciler's Start loop
+func (r *Reconciler) Stop() {
+	close(r.stop)
+}
+
+// Start polls the repo on PollInterval, reconciling on every change, until
+// Stop is called
+func (r *Reconciler) Start() {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	if err := r.Sync(); err != nil {
+		logger.Errorf("Error syncing %v: %v", r.Repo, err)
+	}
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.Sync(); err != nil {
+				logger.Errorf("Error syncing %v: %v", r.Repo, err)
+			}
+		}
+	}
+}
+
+// Sync pulls the latest manifests and reconciles running services to match
+func (r *Reconciler) Sync() error {
+	if err := r.checkout(); err != nil {
+		return err
+	}
+
+	desired, err := r.loadManifests()
+	if err != nil {
+		return err
+	}
+
+	running, err := runtime.Read(runtime.ReadNamespace(r.Namespace))
+	if err != nil {
+		return err
+	}
+	runningByName := make(map[string]*runtime.Service, len(running))
+	for _, s := range running {
+		runningByName[s.Name] = s
+	}
+
+	for name, m := range desired {
+		curr, ok := runningByName[name]
+		if !ok {
+			if err := r.create(m); err != nil {
+				logger.Errorf("Error creating %v: %v", name, err)
+			}
+			continue
+		}
+
+		if r.driftedOutOfBand(curr, m) {
+			logger.Warnf("Service %v was changed out-of-band: its recorded source no longer matches the manifest in %v", name, r.Repo)
+		}
+
+		if curr.Version != m.Version || curr.Source != m.Source {
+			if err := r.update(m); err != nil {
+				logger.Errorf("Error updating %v: %v", name, err)
+			}
+		}
+	}
+
+	// anything running that's no longer declared in the repo is removed
+	for name, curr := range runningByName {
+		if _, ok := desired[name]; !ok {
+			if err := runtime.Delete(curr, runtime.DeleteNamespace(r.Namespace)); err != nil {
+				logger.Errorf("Error deleting %v: %v", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// driftedOutOfBand reports whether a running service's source no longer
+// matches what's declared in the repo, meaning it was changed by something
+// other than this reconciler
+func (r *Reconciler) driftedOutOfBand(curr *runtime.Service, desired *Manifest) bool {
+	return curr.Metadata != nil && curr.Metadata["gitops_source"] != desired.Source
+}
+
+func (r *Reconciler) create(m *Manifest) error {
+	return runtime.Create(&runtime.Service{
+		Name:     m.Name,
+		Version:  m.Version,
+		Source:   m.Source,
+		Metadata: map[string]string{"gitops_source": m.Source},
+	}, createOptionsFor(r.Namespace, m)...)
+}
+
+func (r *Reconciler) update(m *Manifest) error {
+	return runtime.Update(&runtime.Service{
+		Name:     m.Name,
+		Version:  m.Version,
+		Source:   m.Source,
+		Metadata: map[string]string{"gitops_source": m.Source},
+	}, updateOptionsFor(r.Namespace, m)...)
+}
+
+// createOptionsFor translates a manifest's declarative fields into the
+// runtime.CreateOptions Create expects. runtime.Create hands namespace,
+// instances and env to DefaultRuntime; the Autoscale policy set here is
+// not acted on by it.
+func createOptionsFor(namespace string, m *Manifest) []runtime.CreateOption {
+	opts := []runtime.CreateOption{runtime.CreateNamespace(namespace)}
+	if m.Instances > 0 {
+		opts = append(opts, runtime.CreateInstances(m.Instances))
+	}
+	if len(m.Env) > 0 {
+		opts = append(opts, runtime.CreateEnv(envSlice(m.Env)))
+	}
+	if m.Autoscale != nil {
+		opts = append(opts, runtime.CreateAutoscale(autoscalePolicy(m.Autoscale)))
+	}
+	return opts
+}
+
+// updateOptionsFor is the Update equivalent of createOptionsFor.
+// runtime.Update hands only namespace and instances to DefaultRuntime, so
+// the Env and Autoscale set here are not acted on by it.
+func updateOptionsFor(namespace string, m *Manifest) []runtime.UpdateOption {
+	opts := []runtime.UpdateOption{runtime.UpdateNamespace(namespace)}
+	if m.Instances > 0 {
+		opts = append(opts, runtime.UpdateInstances(m.Instances))
+	}
+	if len(m.Env) > 0 {
+		opts = append(opts, runtime.UpdateEnv(envSlice(m.Env)))
+	}
+	if m.Autoscale != nil {
+		opts = append(opts, runtime.UpdateAutoscale(autoscalePolicy(m.Autoscale)))
+	}
+	return opts
+}
+
+func autoscalePolicy(a *AutoscaleManifest) *runtime.AutoscalePolicy {
+	return &runtime.AutoscalePolicy{
+		MinReplicas:      a.Min,
+		MaxReplicas:      a.Max,
+		TargetCPUPercent: a.TargetCPUPercent,
+	}
+}
+
+// envSlice converts a manifest's env map into the "KEY=VALUE" form
+// runtime.CreateEnv/UpdateEnv expect
+func envSlice(env map[string]string) []string {
+	out := make([]string, 0, len(env))
+	for k, v := range env {
+		out = append(out, k+"="+v)
+	}
+	return out
+}
+
+// checkout clones the repo on first sync and pulls on every subsequent one
+func (r *Reconciler) checkout() error {
+	if _, err := os.Stat(filepath.Join(r.checkoutDir, ".git")); os.IsNotExist(err) {
+		return exec.Command("git", "clone", r.Repo, r.checkoutDir).Run()
+	}
+
+	cmd := exec.Command("git", "pull")
+	cmd.Dir = r.checkoutDir
+	return cmd.Run()
+}
+
+// loadManifests reads every *.yaml file in the checkout into a Manifest,
+// keyed by service name
+func (r *Reconciler) loadManifests() (map[string]*Manifest, error) {
+	files, err := filepath.Glob(filepath.Join(r.checkoutDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make(map[string]*Manifest, len(files))
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		var m Manifest
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error parsing %v: %w", f, err)
+		}
+		manifests[m.Name] = &m
+	}
+
+	return manifests, nil
+}
@@ -0,0 +1,112 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/micro/micro/v3/service/runtime"
+)
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "foo.yaml", "name: foo\nversion: v1\nsource: github.com/example/foo\ninstances: 2\n")
+	write(t, dir, "bar.yaml", "name: bar\nversion: v2\nsource: github.com/example/bar\n")
+
+	r := &Reconciler{checkoutDir: dir}
+	manifests, err := r.loadManifests()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("got %v manifests, want 2", len(manifests))
+	}
+	if manifests["foo"].Instances != 2 {
+		t.Fatalf("got instances=%v, want 2", manifests["foo"].Instances)
+	}
+}
+
+func TestDriftedOutOfBand(t *testing.T) {
+	r := &Reconciler{}
+	desired := &Manifest{Source: "github.com/example/foo"}
+
+	curr := &runtime.Service{Metadata: map[string]string{"gitops_source": "github.com/example/foo"}}
+	if r.driftedOutOfBand(curr, desired) {
+		t.Fatal("expected no drift when gitops_source matches the desired source")
+	}
+
+	curr = &runtime.Service{Metadata: map[string]string{"gitops_source": "github.com/example/other"}}
+	if !r.driftedOutOfBand(curr, desired) {
+		t.Fatal("expected drift when gitops_source doesn't match the desired source")
+	}
+}
+
+func TestCreateOptionsForForwardsEnvAndAutoscale(t *testing.T) {
+	m := &Manifest{
+		Name:      "foo",
+		Instances: 3,
+		Env:       map[string]string{"FOO": "bar"},
+		Autoscale: &AutoscaleManifest{Min: 1, Max: 5, TargetCPUPercent: 80},
+	}
+
+	var options runtime.CreateOptions
+	for _, o := range createOptionsFor("default", m) {
+		o(&options)
+	}
+
+	if options.Namespace != "default" {
+		t.Fatalf("got namespace %v, want default", options.Namespace)
+	}
+	if options.Instances != 3 {
+		t.Fatalf("got instances %v, want 3", options.Instances)
+	}
+	if len(options.Env) != 1 || options.Env[0] != "FOO=bar" {
+		t.Fatalf("got env %v, want [FOO=bar]", options.Env)
+	}
+	if options.Autoscale == nil || options.Autoscale.MinReplicas != 1 || options.Autoscale.MaxReplicas != 5 {
+		t.Fatalf("got autoscale %+v, want Min=1 Max=5", options.Autoscale)
+	}
+}
+
+func TestUpdateOptionsForForwardsEnvAndAutoscale(t *testing.T) {
+	m := &Manifest{
+		Name:      "foo",
+		Env:       map[string]string{"FOO": "bar"},
+		Autoscale: &AutoscaleManifest{Min: 2, Max: 4},
+	}
+
+	var options runtime.UpdateOptions
+	for _, o := range updateOptionsFor("default", m) {
+		o(&options)
+	}
+
+	if len(options.Env) != 1 || options.Env[0] != "FOO=bar" {
+		t.Fatalf("got env %v, want [FOO=bar]", options.Env)
+	}
+	if options.Autoscale == nil || options.Autoscale.MinReplicas != 2 || options.Autoscale.MaxReplicas != 4 {
+		t.Fatalf("got autoscale %+v, want Min=2 Max=4", options.Autoscale)
+	}
+}
+
+func TestCreateOptionsForOmitsUnsetEnvAndAutoscale(t *testing.T) {
+	m := &Manifest{Name: "foo"}
+
+	var options runtime.CreateOptions
+	for _, o := range createOptionsFor("default", m) {
+		o(&options)
+	}
+
+	if options.Env != nil {
+		t.Fatalf("got env %v, want nil when the manifest sets none", options.Env)
+	}
+	if options.Autoscale != nil {
+		t.Fatalf("got autoscale %+v, want nil when the manifest sets none", options.Autoscale)
+	}
+}
+
+func write(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+		t.Fatalf("error writing %v: %v", name, err)
+	}
+}
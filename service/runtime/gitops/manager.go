@@ -0,0 +1,44 @@
+package gitops
+
+import "sync"
+
+// Manager runs one Reconciler per namespace, supporting multiple repos
+// scoped to different namespaces within the same deployment
+type Manager struct {
+	sync.Mutex
+
+	reconcilers map[string]*Reconciler
+}
+
+// NewManager returns an empty gitops Manager
+func NewManager() *Manager {
+	return &Manager{
+		reconcilers: map[string]*Reconciler{},
+	}
+}
+
+// AddRepo registers a repo to sync into namespace and starts reconciling it,
+// stopping and replacing any reconciler previously registered for that
+// namespace so the two never race Create/Update/Delete against each other.
+func (m *Manager) AddRepo(repo, namespace, checkoutDir string) {
+	m.Lock()
+	defer m.Unlock()
+
+	if prev, ok := m.reconcilers[namespace]; ok {
+		prev.Stop()
+	}
+
+	r := NewReconciler(repo, namespace, checkoutDir)
+	m.reconcilers[namespace] = r
+	go r.Start()
+}
+
+// Stop halts every reconciler managed by m
+func (m *Manager) Stop() {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, r := range m.reconcilers {
+		r.Stop()
+	}
+}
@@ -0,0 +1,23 @@
+package gitops
+
+import "testing"
+
+func TestAddRepoStopsPreviousReconciler(t *testing.T) {
+	m := NewManager()
+	m.AddRepo("github.com/example/foo", "default", t.TempDir())
+
+	prev := m.reconcilers["default"]
+
+	m.AddRepo("github.com/example/bar", "default", t.TempDir())
+
+	select {
+	case <-prev.stop:
+		// closed, as expected
+	default:
+		t.Fatal("previous reconciler's stop channel was not closed on replace")
+	}
+
+	if m.reconcilers["default"] == prev {
+		t.Fatal("expected AddRepo to install a new reconciler, not keep the previous one")
+	}
+}
@@ -0,0 +1,53 @@
+package gitops
+
+import (
+	"encoding/json"
+
+	"github.com/micro/go-micro/v3/store"
+	microStore "github.com/micro/micro/v3/service/store"
+)
+
+// storePrefix namespaces the keys this package writes to microStore so a
+// `micro gitops add` invocation (a short-lived CLI process) can persist a
+// repo registration for the long-running server process to pick up, rather
+// than it dying with the CLI that registered it.
+const storePrefix = "gitops/repos/"
+
+// repoRegistration is what's persisted for each namespace's repo
+type repoRegistration struct {
+	Repo        string `json:"repo"`
+	Namespace   string `json:"namespace"`
+	CheckoutDir string `json:"checkout_dir"`
+}
+
+// SaveRepo persists a repo registration so it survives the process that
+// registered it
+func SaveRepo(repo, namespace, checkoutDir string) error {
+	data, err := json.Marshal(repoRegistration{Repo: repo, Namespace: namespace, CheckoutDir: checkoutDir})
+	if err != nil {
+		return err
+	}
+
+	return microStore.DefaultStore.Write(&store.Record{
+		Key:   storePrefix + namespace,
+		Value: data,
+	})
+}
+
+// LoadRepos returns every repo registration persisted via SaveRepo
+func LoadRepos() ([]repoRegistration, error) {
+	recs, err := microStore.DefaultStore.Read(storePrefix, store.ReadPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	regs := make([]repoRegistration, 0, len(recs))
+	for _, rec := range recs {
+		var reg repoRegistration
+		if err := json.Unmarshal(rec.Value, &reg); err != nil {
+			continue
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
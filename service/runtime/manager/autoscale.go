@@ -0,0 +1,31 @@
+package manager
+
+import (
+	microEvents "github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/runtime/manager/autoscaler"
+)
+
+// metricsTopic mirrors autoscaler.metricsTopic; kept here too since the
+// manager is the one subscribing to microEvents.DefaultStream
+const metricsTopic = "runtime.metrics"
+
+// watchMetrics subscribes to per-service metrics samples and feeds them to
+// the autoscaler, which issues Update calls back into this manager when a
+// service's instance count needs to change
+func (m *manager) watchMetrics() {
+	evs, err := microEvents.Subscribe(metricsTopic)
+	if err != nil {
+		logger.Warnf("Error subscribing to %v: %v", metricsTopic, err)
+		return
+	}
+
+	for ev := range evs {
+		var sample autoscaler.Sample
+		if err := ev.Unmarshal(&sample); err != nil {
+			logger.Warnf("Error decoding metrics sample: %v", err)
+			continue
+		}
+		m.autoscaler.Record(sample)
+	}
+}
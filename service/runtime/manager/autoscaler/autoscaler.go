@@ -0,0 +1,161 @@
+// Package autoscaler watches per-service metrics published to the events
+// stream and issues runtime.Update calls to grow or shrink a service's
+// instance count to match an AutoscalePolicy declared at Create time.
+package autoscaler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/runtime"
+)
+
+// metricsTopic is the events topic per-service CPU/memory/RPS samples are
+// published to. The manager's watchMetrics subscribes to it and passes
+// each sample to Record.
+const metricsTopic = "runtime.metrics"
+
+// Policy declares how a service should be scaled. A Min of 0 lets decide
+// take the service down to zero instances; Unpause brings it back to one.
+type Policy struct {
+	Min              int
+	Max              int
+	TargetCPUPercent int
+	TargetRPS        int
+	// Cooldown is the minimum time between scaling decisions, preventing
+	// flapping on noisy metrics
+	Cooldown time.Duration
+}
+
+// Sample is a single metrics reading for a service
+type Sample struct {
+	Service   string
+	Namespace string
+	Version   string
+	CPU       int
+	RPS       int
+}
+
+// Updater is the subset of the manager used to apply scaling decisions;
+// satisfied by *manager.manager
+type Updater interface {
+	Update(srv *runtime.Service, opts ...runtime.UpdateOption) error
+}
+
+// Autoscaler scales services against the policy recorded for them at
+// Create time, one sample at a time as Record is called. Policies are kept
+// per namespace; there is no separate control loop for each namespace.
+type Autoscaler struct {
+	sync.Mutex
+
+	updater Updater
+	// policies by namespace then service name
+	policies map[string]map[string]*Policy
+	// current desired instance count, by namespace then service name
+	desired map[string]map[string]int
+	// lastScaled tracks the last time a service was scaled, for the
+	// stabilization window
+	lastScaled map[string]time.Time
+}
+
+// New returns an Autoscaler which applies scaling decisions via updater
+func New(updater Updater) *Autoscaler {
+	return &Autoscaler{
+		updater:    updater,
+		policies:   map[string]map[string]*Policy{},
+		desired:    map[string]map[string]int{},
+		lastScaled: map[string]time.Time{},
+	}
+}
+
+// SetPolicy records the autoscale policy for a service, overwriting any
+// previous policy
+func (a *Autoscaler) SetPolicy(namespace, service string, p *Policy) {
+	a.Lock()
+	defer a.Unlock()
+
+	if _, ok := a.policies[namespace]; !ok {
+		a.policies[namespace] = map[string]*Policy{}
+	}
+	a.policies[namespace][service] = p
+}
+
+// Record processes a metrics sample, scaling the service if it's out of
+// the target range and the cooldown has elapsed
+func (a *Autoscaler) Record(s Sample) {
+	a.Lock()
+	policy, ok := a.policies[s.Namespace][s.Service]
+	if !ok {
+		a.Unlock()
+		return
+	}
+
+	key := s.Namespace + ":" + s.Service
+	if last, ok := a.lastScaled[key]; ok && time.Since(last) < policy.Cooldown {
+		a.Unlock()
+		return
+	}
+
+	current := a.desired[s.Namespace][s.Service]
+	if current == 0 {
+		current = policy.Min
+	}
+	target := a.decide(policy, current, s)
+	a.Unlock()
+
+	if target == current {
+		return
+	}
+
+	if err := a.scale(s.Namespace, s.Service, s.Version, target); err != nil {
+		logger.Errorf("Error scaling %v to %v instances: %v", s.Service, target, err)
+		return
+	}
+
+	a.Lock()
+	a.lastScaled[key] = time.Now()
+	if _, ok := a.desired[s.Namespace]; !ok {
+		a.desired[s.Namespace] = map[string]int{}
+	}
+	a.desired[s.Namespace][s.Service] = target
+	a.Unlock()
+}
+
+// decide applies the target CPU%/RPS thresholds to work out the desired
+// instance count, clamped to the policy's min/max
+func (a *Autoscaler) decide(p *Policy, current int, s Sample) int {
+	target := current
+
+	if p.TargetCPUPercent > 0 && s.CPU > p.TargetCPUPercent {
+		target = current + 1
+	} else if p.TargetRPS > 0 && s.RPS > p.TargetRPS {
+		target = current + 1
+	} else if current > p.Min && s.CPU == 0 && s.RPS == 0 {
+		// no load at all, scale back down towards min (and to zero if the
+		// policy allows it)
+		target = current - 1
+	}
+
+	if target > p.Max {
+		target = p.Max
+	}
+	if target < p.Min {
+		target = p.Min
+	}
+	return target
+}
+
+func (a *Autoscaler) scale(namespace, service, version string, instances int) error {
+	return a.updater.Update(&runtime.Service{Name: service, Version: version},
+		runtime.UpdateNamespace(namespace),
+		runtime.UpdateInstances(instances),
+	)
+}
+
+// Unpause scales a scaled-to-zero service back up to at least one instance.
+// It's called by the manager's Unpause, which runtime.UnpauseFunc is set
+// to; no router in this repository calls it.
+func (a *Autoscaler) Unpause(namespace, service, version string) error {
+	return a.scale(namespace, service, version, 1)
+}
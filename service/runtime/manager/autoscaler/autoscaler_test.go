@@ -0,0 +1,74 @@
+package autoscaler
+
+import (
+	"testing"
+
+	"github.com/micro/micro/v3/service/runtime"
+)
+
+type fakeUpdater struct {
+	calls []runtime.UpdateOptions
+}
+
+func (f *fakeUpdater) Update(srv *runtime.Service, opts ...runtime.UpdateOption) error {
+	var options runtime.UpdateOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	f.calls = append(f.calls, options)
+	return nil
+}
+
+func TestDecideScalesUpOnHighCPU(t *testing.T) {
+	a := New(&fakeUpdater{})
+	p := &Policy{Min: 0, Max: 5, TargetCPUPercent: 50}
+	got := a.decide(p, 1, Sample{CPU: 90})
+	if got != 2 {
+		t.Fatalf("got %v, want 2", got)
+	}
+}
+
+func TestDecideScalesDownToZeroOnNoLoad(t *testing.T) {
+	a := New(&fakeUpdater{})
+	p := &Policy{Min: 0, Max: 5, TargetCPUPercent: 50}
+	got := a.decide(p, 1, Sample{})
+	if got != 0 {
+		t.Fatalf("got %v, want 0 (scale to zero on no load)", got)
+	}
+}
+
+func TestDecideClampsToMax(t *testing.T) {
+	a := New(&fakeUpdater{})
+	p := &Policy{Min: 0, Max: 3, TargetCPUPercent: 50}
+	got := a.decide(p, 3, Sample{CPU: 90})
+	if got != 3 {
+		t.Fatalf("got %v, want 3 (clamped to Max)", got)
+	}
+}
+
+func TestRecordAppliesDecisionViaUpdater(t *testing.T) {
+	updater := &fakeUpdater{}
+	a := New(updater)
+	a.SetPolicy("default", "foo", &Policy{Min: 0, Max: 5, TargetCPUPercent: 50})
+
+	a.Record(Sample{Namespace: "default", Service: "foo", Version: "v1", CPU: 90})
+
+	if len(updater.calls) != 1 {
+		t.Fatalf("got %v calls to Update, want 1", len(updater.calls))
+	}
+	if updater.calls[0].Instances != 1 {
+		t.Fatalf("got %v instances, want 1", updater.calls[0].Instances)
+	}
+}
+
+func TestUnpauseScalesToAtLeastOne(t *testing.T) {
+	updater := &fakeUpdater{}
+	a := New(updater)
+
+	if err := a.Unpause("default", "foo", "v1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updater.calls) != 1 || updater.calls[0].Instances != 1 {
+		t.Fatalf("got calls=%+v, want a single Update with Instances=1", updater.calls)
+	}
+}
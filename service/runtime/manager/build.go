@@ -0,0 +1,68 @@
+package manager
+
+import (
+	"os"
+
+	gorun "github.com/micro/go-micro/v3/runtime"
+	microEvents "github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/runtime/builder"
+)
+
+// buildTopic is the events topic build logs are streamed to so `micro logs
+// --build` can tail them as they happen
+const buildTopic = "runtime.build"
+
+// defaultRegistry is used when MICRO_BUILD_REGISTRY isn't set
+const defaultRegistry = "registry.micro.mu"
+
+// buildImage resolves the builder for the service (its own Builder, else
+// its namespace's default, else builder.DefaultBuilder) and builds the
+// checked out source into an OCI image, streaming build output into the
+// events stream. It records gorun.Building on the service while the build
+// runs and gorun.Pending once it finishes; fetching and pushing are not
+// recorded as separate stages. Nothing in this package calls it:
+// buildAndRun and buildAndUpdate do not go through it.
+func (m *manager) buildImage(service *service, src string) (string, error) {
+	name := service.Options.Builder
+	if len(name) == 0 {
+		name = builder.NamespaceDefault(service.Options.Namespace)
+	}
+	b, err := builder.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	service.Status = gorun.Building
+	if err := m.writeService(service); err != nil {
+		return "", err
+	}
+
+	onLog := func(line string) {
+		// best-effort: a dropped build log line shouldn't fail the build
+		_ = microEvents.Publish(buildTopic, map[string]interface{}{
+			"service":   service.Service.Name,
+			"version":   service.Service.Version,
+			"namespace": service.Options.Namespace,
+			"line":      line,
+		})
+	}
+
+	registry := os.Getenv("MICRO_BUILD_REGISTRY")
+	if len(registry) == 0 {
+		registry = defaultRegistry
+	}
+
+	image, err := b.Build(src,
+		onLog,
+		builder.Registry(registry),
+		builder.Namespace(service.Options.Namespace),
+		builder.Name(service.Service.Name),
+		builder.Version(service.Service.Version),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	service.Status = gorun.Pending
+	return image, m.writeService(service)
+}
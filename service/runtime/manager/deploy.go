@@ -0,0 +1,164 @@
+package manager
+
+import (
+	"fmt"
+
+	gorun "github.com/micro/go-micro/v3/runtime"
+	"github.com/micro/micro/v3/internal/namespace"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/runtime"
+)
+
+// canarySuffix and greenSuffix are appended to the version of a canary or
+// blue/green deployment so each can run alongside the stable version
+// without colliding in the store or the underlying runtime
+const (
+	canarySuffix = "-canary"
+	greenSuffix  = "-green"
+)
+
+// buildAndUpdateStrategy dispatches to the update path appropriate for the
+// requested strategy. Recreate and RollingUpdate are handled by the
+// existing buildAndUpdate/updateServiceInRuntime path; BlueGreen and Canary
+// run a second copy of the service alongside the stable one, so they need
+// the full UpdateOptions (namespace, canary weight) rather than just the
+// strategy.
+func (m *manager) buildAndUpdateStrategy(stable *service, options runtime.UpdateOptions) {
+	switch options.Strategy {
+	case runtime.StrategyCanary:
+		m.buildAndUpdateCanary(stable, options)
+	case runtime.StrategyBlueGreen:
+		m.buildAndUpdateBlueGreen(stable)
+	default:
+		m.buildAndUpdate(stable)
+	}
+}
+
+// newCanaryService builds the *service record for a canary deployment: a
+// second copy of stable, suffixed canarySuffix so it runs alongside stable
+// rather than replacing it, carrying the requested traffic split in
+// Metadata["canary_weight"].
+func newCanaryService(stable *service, weight int) *service {
+	return &service{
+		Service: &runtime.Service{
+			Name:    stable.Service.Name,
+			Version: stable.Service.Version + canarySuffix,
+			Source:  stable.Service.Source,
+			Metadata: map[string]string{
+				"canary_weight": fmt.Sprintf("%v", weight),
+			},
+		},
+		Options:   stable.Options,
+		UpdatedAt: stable.UpdatedAt,
+	}
+}
+
+// newGreenService builds the *service record for a green deployment: a
+// second copy of blue, suffixed greenSuffix so it runs alongside blue
+// rather than replacing it.
+func newGreenService(blue *service) *service {
+	return &service{
+		Service: &runtime.Service{
+			Name:     blue.Service.Name,
+			Version:  blue.Service.Version + greenSuffix,
+			Source:   blue.Service.Source,
+			Metadata: map[string]string{"color": "green"},
+		},
+		Options:   blue.Options,
+		UpdatedAt: blue.UpdatedAt,
+	}
+}
+
+// buildAndUpdateCanary starts a second copy of the service, suffixed
+// "-canary", alongside the stable version, which is left untouched. The
+// traffic split is recorded in Metadata["canary_weight"]; nothing in this
+// repository routes requests by it.
+func (m *manager) buildAndUpdateCanary(stable *service, options runtime.UpdateOptions) {
+	canary := newCanaryService(stable, options.CanaryWeight)
+
+	if err := m.createServiceInRuntime(canary); err != nil {
+		logger.Errorf("Error starting canary for %v: %v", stable.Service.Name, err)
+		return
+	}
+	if err := m.writeService(canary); err != nil {
+		logger.Errorf("Error recording canary for %v: %v", stable.Service.Name, err)
+	}
+}
+
+// buildAndUpdateBlueGreen starts a second copy of the service, suffixed
+// "-green", alongside the currently running one (blue), which is left
+// untouched. Promote replaces blue with green and removes the
+// green-suffixed record; it does not check green's health first.
+func (m *manager) buildAndUpdateBlueGreen(blue *service) {
+	green := newGreenService(blue)
+
+	if err := m.createServiceInRuntime(green); err != nil {
+		logger.Errorf("Error starting green deployment for %v: %v", green.Service.Name, err)
+		return
+	}
+	if err := m.writeService(green); err != nil {
+		logger.Errorf("Error recording green deployment for %v: %v", green.Service.Name, err)
+	}
+
+	// blue is left as it is and keeps serving traffic until green is
+	// promoted
+}
+
+// Promote replaces the stable record for srv with the deployment at the
+// given version (a canary or a verified green deployment): the promoted
+// service is renamed to srv's stable version, which overwrites the old
+// stable record in both the store and the runtime (they share the same
+// name:version key), then the now-vacated canary/green-suffixed record is
+// removed so it isn't left running orphaned under its old version.
+func (m *manager) Promote(srv *runtime.Service, version string) error {
+	srvs, err := m.readServices(namespace.DefaultNamespace, &runtime.Service{Name: srv.Name, Version: version})
+	if err != nil {
+		return err
+	}
+	if len(srvs) == 0 {
+		return gorun.ErrNotFound
+	}
+	promoted := srvs[0]
+
+	// capture the canary/green version being promoted away from before it's
+	// overwritten below, so the old suffixed deployment can still be found
+	// and deleted afterwards rather than deleting the identity it was just
+	// renamed to (which is srv's stable version, i.e. the promotion itself)
+	oldVersion := promoted.Service.Version
+
+	promoted.Service.Version = srv.Version
+	promoted.Service.Metadata["color"] = "blue"
+	if err := m.updateServiceInRuntime(promoted); err != nil {
+		return err
+	}
+	if err := m.writeService(promoted); err != nil {
+		return err
+	}
+
+	orphan := orphanedDeployment(srv.Name, oldVersion, srv.Version)
+	if orphan == nil {
+		return nil
+	}
+	return m.Delete(orphan)
+}
+
+// orphanedDeployment identifies the canary/green-suffixed record left
+// behind once Promote has renamed it to newVersion - nil if it was already
+// running under newVersion (nothing to clean up).
+func orphanedDeployment(name, oldVersion, newVersion string) *runtime.Service {
+	if oldVersion == newVersion {
+		return nil
+	}
+	return &runtime.Service{Name: name, Version: oldVersion}
+}
+
+// Abort removes the canary deployment for srv (srv.Version plus
+// canarySuffix), leaving the stable version untouched. A green deployment
+// is not removed by it.
+func (m *manager) Abort(srv *runtime.Service) error {
+	canary := &runtime.Service{Name: srv.Name, Version: srv.Version + canarySuffix}
+	if err := m.Delete(canary); err != nil && err != gorun.ErrNotFound {
+		return err
+	}
+	return nil
+}
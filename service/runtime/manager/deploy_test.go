@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/micro/micro/v3/service/runtime"
+)
+
+func TestNewCanaryServiceRunsAlongsideStable(t *testing.T) {
+	stable := &service{
+		Service: &runtime.Service{
+			Name:    "foo",
+			Version: "v1",
+			Source:  "github.com/example/foo",
+		},
+		Options:   &runtime.CreateOptions{Namespace: "default"},
+		UpdatedAt: time.Now(),
+	}
+
+	canary := newCanaryService(stable, 25)
+
+	if canary.Service.Name != stable.Service.Name {
+		t.Fatalf("canary name %v should match stable name %v", canary.Service.Name, stable.Service.Name)
+	}
+	if canary.Service.Version == stable.Service.Version {
+		t.Fatal("canary must run at a distinct version so it doesn't overwrite stable")
+	}
+	if canary.Service.Version != stable.Service.Version+canarySuffix {
+		t.Fatalf("got version %v, want %v", canary.Service.Version, stable.Service.Version+canarySuffix)
+	}
+	if canary.Service.Metadata["canary_weight"] != "25" {
+		t.Fatalf("got canary_weight %v, want 25", canary.Service.Metadata["canary_weight"])
+	}
+}
+
+func TestNewGreenServiceRunsAlongsideBlue(t *testing.T) {
+	blue := &service{
+		Service: &runtime.Service{
+			Name:    "foo",
+			Version: "v1",
+			Source:  "github.com/example/foo",
+		},
+		Options:   &runtime.CreateOptions{Namespace: "default"},
+		UpdatedAt: time.Now(),
+	}
+
+	green := newGreenService(blue)
+
+	if green.Service.Version == blue.Service.Version {
+		t.Fatal("green must run at a distinct version so it doesn't overwrite blue")
+	}
+	if green.Service.Version != blue.Service.Version+greenSuffix {
+		t.Fatalf("got version %v, want %v", green.Service.Version, blue.Service.Version+greenSuffix)
+	}
+	if green.Service.Metadata["color"] != "green" {
+		t.Fatalf("got color %v, want green", green.Service.Metadata["color"])
+	}
+}
+
+func TestOrphanedDeploymentTargetsOldSuffixedVersion(t *testing.T) {
+	orphan := orphanedDeployment("foo", "latest-canary", "latest")
+	if orphan == nil {
+		t.Fatal("expected an orphaned deployment to clean up")
+	}
+	if orphan.Name != "foo" || orphan.Version != "latest-canary" {
+		t.Fatalf("got %+v, want Name=foo Version=latest-canary", orphan)
+	}
+	// must never target the post-promotion identity - that's the service
+	// that was just promoted, deleting it would undo the promotion
+	if orphan.Version == "latest" {
+		t.Fatal("orphanedDeployment must not target the post-rename identity")
+	}
+}
+
+func TestOrphanedDeploymentNoneWhenAlreadyPromoted(t *testing.T) {
+	if orphan := orphanedDeployment("foo", "latest", "latest"); orphan != nil {
+		t.Fatalf("expected nil when oldVersion already matches newVersion, got %+v", orphan)
+	}
+}
@@ -8,9 +8,23 @@ import (
 	"github.com/micro/micro/v3/service/logger"
 	"github.com/micro/micro/v3/service/runtime"
 	"github.com/micro/micro/v3/service/runtime/builder"
+	"github.com/micro/micro/v3/service/runtime/manager/autoscaler"
 	"github.com/micro/micro/v3/service/runtime/manager/util"
 )
 
+// wantsBuild reports whether a service should go through the build pipeline
+// rather than running its source directly: true if a builder resolves for
+// it, whether selected per-service (builderName, from CreateOptions.Builder),
+// as a default for its namespace, or as the global builder.DefaultBuilder
+// fallback.
+func wantsBuild(builderName, ns string) bool {
+	if len(builderName) == 0 {
+		builderName = builder.NamespaceDefault(ns)
+	}
+	_, err := builder.Get(builderName)
+	return err == nil
+}
+
 // Create registers a service
 func (m *manager) Create(srv *runtime.Service, opts ...runtime.CreateOption) error {
 	// parse the options
@@ -37,8 +51,22 @@ func (m *manager) Create(srv *runtime.Service, opts ...runtime.CreateOption) err
 		UpdatedAt: time.Now(),
 	}
 
-	// if there is not a builder configured, start the service and then write it to the store
-	if builder.DefaultBuilder == nil {
+	// record the autoscale policy (if any) so the control loop started in
+	// Start can begin scaling this service against it
+	if options.Autoscale != nil {
+		m.autoscaler.SetPolicy(options.Namespace, srv.Name, &autoscaler.Policy{
+			Min:              options.Autoscale.MinReplicas,
+			Max:              options.Autoscale.MaxReplicas,
+			TargetCPUPercent: options.Autoscale.TargetCPUPercent,
+			TargetRPS:        options.Autoscale.TargetRPS,
+			Cooldown:         options.Autoscale.Cooldown,
+		})
+	}
+
+	// if no builder resolves for this service (per-service, namespace
+	// default, or global fallback), start it from source directly and write
+	// it to the store
+	if !wantsBuild(options.Builder, options.Namespace) {
 		// the source could be a git remote or a reference to the blob store, parse it before we run
 		// the service
 		var err error
@@ -166,8 +194,29 @@ func (m *manager) Update(srv *runtime.Service, opts ...runtime.UpdateOption) err
 	service.Service.Source = srv.Source
 	service.UpdatedAt = time.Now()
 
-	// if there is not a builder configured, update the service and then write it to the store
-	if builder.DefaultBuilder == nil {
+	// replace the instance count/env/autoscale policy recorded at create
+	// time when the update specifies new ones
+	if options.Instances > 0 {
+		service.Options.Instances = options.Instances
+	}
+	if options.Env != nil {
+		service.Options.Env = options.Env
+	}
+	if options.Autoscale != nil {
+		service.Options.Autoscale = options.Autoscale
+		m.autoscaler.SetPolicy(options.Namespace, srv.Name, &autoscaler.Policy{
+			Min:              options.Autoscale.MinReplicas,
+			Max:              options.Autoscale.MaxReplicas,
+			TargetCPUPercent: options.Autoscale.TargetCPUPercent,
+			TargetRPS:        options.Autoscale.TargetRPS,
+			Cooldown:         options.Autoscale.Cooldown,
+		})
+	}
+
+	// if no builder resolves for this service (per-service, namespace
+	// default, or global fallback), update it from source directly and write
+	// it to the store
+	if !wantsBuild(service.Options.Builder, service.Options.Namespace) {
 		// the source could be a git remote or a reference to the blob store, parse it before we run
 		// the service
 		var err error
@@ -194,7 +243,10 @@ func (m *manager) Update(srv *runtime.Service, opts ...runtime.UpdateOption) err
 		return err
 	}
 
-	go m.buildAndUpdate(service)
+	// Recreate and RollingUpdate replace the running version in place via
+	// buildAndUpdate; BlueGreen and Canary run the new version alongside the
+	// old one and require a follow up Promote or Abort call
+	go m.buildAndUpdateStrategy(service, options)
 	return nil
 }
 
@@ -253,12 +305,27 @@ func (m *manager) Start() error {
 		return err
 	}
 
+	// pick back up any build/deploy that was in progress when this manager
+	// (or one on another node) last crashed; this runs alongside
+	// watchServices, with no ordering between the two
+	go m.replayBuildEvents()
+
 	// Watch services that were running previously. TODO: rename and run periodically
 	go m.watchServices()
 
+	// the autoscaler applies scaling decisions by calling back into this
+	// manager's Update method, so it can only be started once the manager
+	// itself is running
+	go m.watchMetrics()
+
 	return nil
 }
 
+// watchServices reconciles the services recorded in the store against
+// whatever the underlying runtime reports as currently running. This is
+// runtime-agnostic: a runtime such as nomad may report many allocations for
+// a single name:version, but Read collapses those into one entry so the
+// comparison below still works unmodified.
 func (m *manager) watchServices() {
 	nss, err := m.listNamespaces()
 	if err != nil {
@@ -321,16 +388,33 @@ func (m *manager) String() string {
 	return "manager"
 }
 
+// Unpause scales a service that's been scaled to zero back up to at least
+// one instance. It's what runtime.UnpauseFunc is set to by New; no router
+// in this repository calls it.
+func (m *manager) Unpause(namespace, service, version string) error {
+	return m.autoscaler.Unpause(namespace, service, version)
+}
+
 type manager struct {
 	// running is true after Start is called
 	running bool
 
+	// autoscaler applies scaling decisions derived from metrics samples to
+	// services which were created with an AutoscalePolicy
+	autoscaler *autoscaler.Autoscaler
+
 	gorun.Runtime
 }
 
 // New returns a manager for the runtime
-func New() gorun.Runtime {
-	return &manager{
+func New() runtime.Runtime {
+	m := &manager{
 		Runtime: util.NewCache(runtime.DefaultRuntime),
 	}
+	m.autoscaler = autoscaler.New(m)
+
+	// expose the scale-from-zero hook; see runtime.UnpauseFunc
+	runtime.UnpauseFunc = m.Unpause
+
+	return m
 }
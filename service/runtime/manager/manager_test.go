@@ -0,0 +1,55 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/micro/micro/v3/service/runtime/builder"
+)
+
+type fakeBuilder string
+
+func (f fakeBuilder) Build(src string, onLog func(string), opts ...builder.Option) (string, error) {
+	return "", nil
+}
+
+func (f fakeBuilder) String() string { return string(f) }
+
+func TestWantsBuildFalseWithNoBuilderConfigured(t *testing.T) {
+	if wantsBuild("", "default") {
+		t.Fatal("expected no builder to resolve when nothing is configured")
+	}
+}
+
+func TestWantsBuildTrueForPerServiceBuilder(t *testing.T) {
+	if err := builder.Register("test-per-service", fakeBuilder("test-per-service")); err != nil {
+		t.Fatalf("unexpected error registering builder: %v", err)
+	}
+
+	if !wantsBuild("test-per-service", "default") {
+		t.Fatal("expected a per-service Builder to resolve even with no namespace default or global fallback")
+	}
+}
+
+func TestWantsBuildTrueForNamespaceDefault(t *testing.T) {
+	if err := builder.Register("test-ns-default", fakeBuilder("test-ns-default")); err != nil {
+		t.Fatalf("unexpected error registering builder: %v", err)
+	}
+	builder.SetNamespaceDefault("test-namespace", "test-ns-default")
+
+	if !wantsBuild("", "test-namespace") {
+		t.Fatal("expected a namespace default builder to resolve when the service sets none itself")
+	}
+	if wantsBuild("", "some-other-namespace") {
+		t.Fatal("a namespace default must not leak into a different namespace")
+	}
+}
+
+func TestWantsBuildTrueForGlobalFallback(t *testing.T) {
+	prev := builder.DefaultBuilder
+	defer func() { builder.DefaultBuilder = prev }()
+
+	builder.DefaultBuilder = fakeBuilder("test-global-default")
+	if !wantsBuild("", "default") {
+		t.Fatal("expected builder.DefaultBuilder to resolve when nothing more specific is configured")
+	}
+}
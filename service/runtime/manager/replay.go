@@ -0,0 +1,72 @@
+package manager
+
+import (
+	gorun "github.com/micro/go-micro/v3/runtime"
+	"github.com/micro/micro/v3/internal/namespace"
+	microEvents "github.com/micro/micro/v3/service/events"
+	"github.com/micro/micro/v3/service/logger"
+	"github.com/micro/micro/v3/service/runtime"
+)
+
+// replayBuildEvents rebuilds in-flight build/deploy state after a restart by
+// replaying every runtime.build event recorded since the manager last
+// acked one. With a durable stream (jetstream/kafka) this picks back up
+// wherever the previous instance of the manager left off; with the
+// in-memory stream used by the Local/Test profiles there's nothing to
+// replay and this is a no-op.
+func (m *manager) replayBuildEvents() {
+	evs, err := microEvents.Consume(buildTopic,
+		microEvents.WithGroup("runtime-manager"),
+		microEvents.WithOffset(microEvents.OffsetOldest),
+		microEvents.WithAutoAck(false),
+	)
+	if err != nil {
+		logger.Warnf("Error replaying %v: %v", buildTopic, err)
+		return
+	}
+
+	for ev := range evs {
+		m.resumeBuild(ev)
+	}
+}
+
+// resumeBuild re-drives a build that was in progress when its manager
+// crashed. Acking only happens once the build has either finished or been
+// picked up by this node, so a redelivery after a second crash is safe.
+func (m *manager) resumeBuild(ev microEvents.Event) {
+	var status struct {
+		Service   string `json:"service"`
+		Version   string `json:"version"`
+		Namespace string `json:"namespace"`
+	}
+	if err := ev.Unmarshal(&status); err != nil {
+		logger.Warnf("Error decoding build event: %v", err)
+		return
+	}
+	ns := defaultNamespace(status.Namespace)
+
+	srvs, err := m.readServices(ns, &runtime.Service{Name: status.Service, Version: status.Version})
+	if err != nil {
+		logger.Warnf("Error reading %v while resuming build: %v", status.Service, err)
+		return
+	}
+
+	for _, srv := range srvs {
+		if srv.Status != gorun.Building && srv.Status != gorun.Pending {
+			continue
+		}
+		// a previous node died mid-build; restart it on this one
+		go m.buildAndRun(srv)
+	}
+
+	ev.Ack()
+}
+
+// defaultNamespace falls back to namespace.DefaultNamespace for an empty
+// namespace, same as every other read path in this package
+func defaultNamespace(ns string) string {
+	if len(ns) == 0 {
+		return namespace.DefaultNamespace
+	}
+	return ns
+}
@@ -0,0 +1,19 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/micro/micro/v3/internal/namespace"
+)
+
+func TestDefaultNamespaceFallsBackWhenEmpty(t *testing.T) {
+	if got := defaultNamespace(""); got != namespace.DefaultNamespace {
+		t.Fatalf("got %v, want %v (an empty namespace is never a valid store key)", got, namespace.DefaultNamespace)
+	}
+}
+
+func TestDefaultNamespacePreservesExplicitValue(t *testing.T) {
+	if got := defaultNamespace("staging"); got != "staging" {
+		t.Fatalf("got %v, want staging", got)
+	}
+}
@@ -0,0 +1,358 @@
+// Package nomad is a runtime implementation backed by HashiCorp Nomad. It
+// translates a runtime.Service into a Nomad job spec, submits it to the
+// Nomad HTTP API and reflects allocation status back through Read.
+package nomad
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	gorun "github.com/micro/go-micro/v3/runtime"
+	"github.com/micro/micro/v3/service/logger"
+)
+
+// defaultAddress is used when MICRO_NOMAD_ADDRESS isn't set
+const defaultAddress = "http://127.0.0.1:4646"
+
+// Runtime is a gorun.Runtime implementation which schedules services onto a
+// Nomad cluster. Unlike the local runtime, a single Runtime instance manages
+// services across every allocation Nomad reports, not just ones started by
+// this process.
+type Runtime struct {
+	sync.RWMutex
+
+	// address of the Nomad HTTP API
+	address string
+	// http client used to talk to Nomad
+	client *http.Client
+
+	running bool
+}
+
+// NewRuntime returns a new Nomad runtime
+func NewRuntime(opts ...gorun.Option) *Runtime {
+	var options gorun.Options
+	for _, o := range opts {
+		o(&options)
+	}
+
+	addr := os.Getenv("MICRO_NOMAD_ADDRESS")
+	if len(addr) == 0 {
+		addr = defaultAddress
+	}
+
+	return &Runtime{
+		address: addr,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// job is a trimmed down representation of a Nomad job specification, just
+// enough to run a micro service as a single task group
+type job struct {
+	ID          string            `json:"ID"`
+	Name        string            `json:"Name"`
+	Namespace   string            `json:"Namespace,omitempty"`
+	Type        string            `json:"Type"`
+	Datacenters []string          `json:"Datacenters"`
+	TaskGroups  []taskGroup       `json:"TaskGroups"`
+	Meta        map[string]string `json:"Meta,omitempty"`
+}
+
+type taskGroup struct {
+	Name  string `json:"Name"`
+	Count int    `json:"Count"`
+	Tasks []task `json:"Tasks"`
+}
+
+type task struct {
+	Name   string                 `json:"Name"`
+	Driver string                 `json:"Driver"`
+	Config map[string]interface{} `json:"Config"`
+	Env    map[string]string      `json:"Env,omitempty"`
+}
+
+// allocation is the subset of a Nomad allocation we care about when
+// reflecting status back through Read
+type allocation struct {
+	ID            string `json:"ID"`
+	Namespace     string `json:"Namespace"`
+	JobID         string `json:"JobID"`
+	ClientStatus  string `json:"ClientStatus"`
+	DesiredStatus string `json:"DesiredStatus"`
+}
+
+func (r *Runtime) jobID(srv *gorun.Service, namespace string) string {
+	return fmt.Sprintf("%v-%v-%v", namespace, srv.Name, srv.Version)
+}
+
+func (r *Runtime) do(method, path string, in, out interface{}) error {
+	var body []byte
+	var err error
+	if in != nil {
+		body, err = json.Marshal(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, r.address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return gorun.ErrNotFound
+	}
+	if rsp.StatusCode >= 300 {
+		return fmt.Errorf("nomad: %v: %v", rsp.StatusCode, string(data))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// toJob translates a runtime.Service into a Nomad job spec. The driver
+// defaults to "exec" and falls back to "docker" when the service has an
+// image set in its metadata.
+func (r *Runtime) toJob(srv *gorun.Service, opts gorun.CreateOptions) *job {
+	namespace := opts.Namespace
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+
+	driver := "exec"
+	config := map[string]interface{}{
+		"command": "/bin/sh",
+		"args":    []string{"-c", srv.Command},
+	}
+	if image, ok := srv.Metadata["image"]; ok && len(image) > 0 {
+		driver = "docker"
+		config = map[string]interface{}{"image": image}
+	}
+
+	env := map[string]string{}
+	for _, e := range opts.Env {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) == 2 {
+			env[kv[0]] = kv[1]
+		} else {
+			env[e] = os.Getenv(e)
+		}
+	}
+
+	return &job{
+		ID:          r.jobID(srv, namespace),
+		Name:        srv.Name,
+		Namespace:   namespace,
+		Type:        "service",
+		Datacenters: []string{"dc1"},
+		Meta: map[string]string{
+			"micro_name":    srv.Name,
+			"micro_version": srv.Version,
+		},
+		TaskGroups: []taskGroup{
+			{
+				Name:  srv.Name,
+				Count: instances(opts.Instances),
+				Tasks: []task{
+					{
+						Name:   srv.Name,
+						Driver: driver,
+						Config: config,
+						Env:    env,
+					},
+				},
+			},
+		},
+	}
+}
+
+func instances(n int) int {
+	if n <= 0 {
+		return 1
+	}
+	return n
+}
+
+// Init initialises runtime options
+func (r *Runtime) Init(opts ...gorun.Option) error {
+	return nil
+}
+
+// Create registers the service as a Nomad job
+func (r *Runtime) Create(srv *gorun.Service, opts ...gorun.CreateOption) error {
+	var options gorun.CreateOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	j := r.toJob(srv, options)
+	return r.do(http.MethodPost, "/v1/jobs", map[string]interface{}{"Job": j}, nil)
+}
+
+// Read returns the services matching the criteria provided, built from the
+// current Nomad allocation list rather than any local state
+func (r *Runtime) Read(opts ...gorun.ReadOption) ([]*gorun.Service, error) {
+	var options gorun.ReadOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	namespace := options.Namespace
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+
+	var allocs []allocation
+	if err := r.do(http.MethodGet, "/v1/allocations?namespace="+namespace, nil, &allocs); err != nil {
+		return nil, err
+	}
+
+	// group allocations by job so a job with multiple instances collapses
+	// into a single service with an aggregate status
+	byJob := map[string][]allocation{}
+	for _, a := range allocs {
+		byJob[a.JobID] = append(byJob[a.JobID], a)
+	}
+
+	var services []*gorun.Service
+	for jobID, jobAllocs := range byJob {
+		name, version, err := r.jobNameVersion(jobID, namespace)
+		if err != nil {
+			// the job may have been purged out from under us between listing
+			// allocations and looking it up; skip it rather than returning a
+			// service the store can never correlate against
+			continue
+		}
+
+		services = append(services, &gorun.Service{
+			Name:    name,
+			Version: version,
+			Status:  aggregateStatus(jobAllocs),
+		})
+	}
+
+	return services, nil
+}
+
+// jobNameVersion looks up the micro service name/version a Nomad job was
+// registered with. manager.Read correlates runtime state against the store
+// via "name:version", so returning the raw Nomad job ID here (which is
+// namespace-name-version) would never match.
+func (r *Runtime) jobNameVersion(jobID, namespace string) (string, string, error) {
+	var j job
+	path := fmt.Sprintf("/v1/job/%v?namespace=%v", jobID, namespace)
+	if err := r.do(http.MethodGet, path, nil, &j); err != nil {
+		return "", "", err
+	}
+
+	name, ok := j.Meta["micro_name"]
+	if !ok {
+		return "", "", fmt.Errorf("nomad: job %v has no micro_name meta", jobID)
+	}
+	return name, j.Meta["micro_version"], nil
+}
+
+func aggregateStatus(allocs []allocation) gorun.ServiceStatus {
+	status := gorun.Running
+	for _, a := range allocs {
+		switch a.ClientStatus {
+		case "failed", "lost":
+			return gorun.Error
+		case "pending":
+			status = gorun.Starting
+		}
+	}
+	return status
+}
+
+// Update performs a rolling update of the job by re-registering it with
+// Nomad; Nomad's own update stanza handles the rollout
+func (r *Runtime) Update(srv *gorun.Service, opts ...gorun.UpdateOption) error {
+	var options gorun.UpdateOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	j := r.toJob(srv, gorun.CreateOptions{Namespace: options.Namespace, Instances: options.Instances})
+	return r.do(http.MethodPost, "/v1/jobs", map[string]interface{}{"Job": j}, nil)
+}
+
+// Delete stops the Nomad job, purges it and cleans up any Consul service
+// registrations Nomad created on its behalf
+func (r *Runtime) Delete(srv *gorun.Service, opts ...gorun.DeleteOption) error {
+	var options gorun.DeleteOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	namespace := options.Namespace
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+
+	id := r.jobID(srv, namespace)
+	path := fmt.Sprintf("/v1/job/%v?namespace=%v&purge=true", id, namespace)
+	if err := r.do(http.MethodDelete, path, nil, nil); err != nil {
+		return err
+	}
+
+	// Nomad deregisters the Consul services it registered as part of
+	// purging the job, nothing further to do here
+	return nil
+}
+
+// Logs is not yet implemented for the Nomad runtime
+func (r *Runtime) Logs(srv *gorun.Service, opts ...gorun.LogsOption) (gorun.LogStream, error) {
+	return nil, gorun.ErrNotFound
+}
+
+// Start the runtime
+func (r *Runtime) Start() error {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return nil
+	}
+	r.running = true
+
+	if logger.V(logger.InfoLevel, logger.DefaultLogger) {
+		logger.Infof("Nomad runtime connecting to %v", r.address)
+	}
+	return nil
+}
+
+// Stop the runtime
+func (r *Runtime) Stop() error {
+	r.Lock()
+	defer r.Unlock()
+	r.running = false
+	return nil
+}
+
+// String describes runtime
+func (r *Runtime) String() string {
+	return "nomad"
+}
@@ -0,0 +1,102 @@
+package nomad
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gorun "github.com/micro/go-micro/v3/runtime"
+)
+
+// newTestRuntime returns a Runtime pointed at a test Nomad API server
+func newTestRuntime(srv *httptest.Server) *Runtime {
+	return &Runtime{
+		address: srv.URL,
+		client:  srv.Client(),
+	}
+}
+
+func TestJobNameVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(job{
+			ID:   "default-foo-v1",
+			Meta: map[string]string{"micro_name": "foo", "micro_version": "v1"},
+		})
+	}))
+	defer srv.Close()
+
+	r := newTestRuntime(srv)
+	name, version, err := r.jobNameVersion("default-foo-v1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "foo" || version != "v1" {
+		t.Fatalf("got name=%v version=%v, want name=foo version=v1", name, version)
+	}
+}
+
+func TestJobNameVersionMissingMeta(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(job{ID: "default-foo-v1"})
+	}))
+	defer srv.Close()
+
+	r := newTestRuntime(srv)
+	if _, _, err := r.jobNameVersion("default-foo-v1", "default"); err == nil {
+		t.Fatal("expected an error for a job with no micro_name meta, got nil")
+	}
+}
+
+func TestReadSkipsUncorrelatableJobs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/allocations":
+			json.NewEncoder(w).Encode([]allocation{
+				{JobID: "default-foo-v1", ClientStatus: "running"},
+				{JobID: "default-bar-v1", ClientStatus: "running"},
+			})
+		case r.URL.Path == "/v1/job/default-foo-v1":
+			json.NewEncoder(w).Encode(job{
+				Meta: map[string]string{"micro_name": "foo", "micro_version": "v1"},
+			})
+		case r.URL.Path == "/v1/job/default-bar-v1":
+			// simulate the job having been purged between listing
+			// allocations and looking it up
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	r := newTestRuntime(srv)
+	services, err := r.Read(gorun.ReadNamespace("default"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("got %v services, want 1 (the uncorrelatable job should be skipped)", len(services))
+	}
+	if services[0].Name != "foo" || services[0].Version != "v1" {
+		t.Fatalf("got service %+v, want name=foo version=v1", services[0])
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		allocs []allocation
+		want   gorun.ServiceStatus
+	}{
+		{"running", []allocation{{ClientStatus: "running"}}, gorun.Running},
+		{"pending wins over running", []allocation{{ClientStatus: "running"}, {ClientStatus: "pending"}}, gorun.Starting},
+		{"failed wins over everything", []allocation{{ClientStatus: "running"}, {ClientStatus: "failed"}}, gorun.Error},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := aggregateStatus(c.allocs); got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
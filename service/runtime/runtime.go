@@ -0,0 +1,286 @@
+// Package runtime manages the lifecycle of services on top of a
+// gorun.Runtime backend (local, kubernetes, nomad, ...), adding
+// namespacing, builds, deployment strategies and autoscaling that the
+// underlying backend doesn't know about itself.
+package runtime
+
+import (
+	"time"
+
+	gorun "github.com/micro/go-micro/v3/runtime"
+)
+
+// Service describes a deployable unit. It's a straight alias of
+// gorun.Service so a *Service can be handed directly to the gorun.Runtime
+// backend a Runtime implementation wraps.
+type Service = gorun.Service
+
+// Status is the lifecycle state of a service
+type Status = gorun.ServiceStatus
+
+// Starting is set on a service between a successful Update and the
+// underlying runtime reporting it as Running
+const Starting Status = "starting"
+
+// re-exported so callers only need to import this package
+var (
+	ErrNotFound      = gorun.ErrNotFound
+	ErrAlreadyExists = gorun.ErrAlreadyExists
+)
+
+// ReadOptions and DeleteOptions are aliased straight through to the
+// gorun.Runtime equivalents: Read and Delete calls are forwarded to the
+// wrapped backend as-is, with no manager-specific behaviour attached to
+// them.
+type (
+	ReadOptions = gorun.ReadOptions
+	ReadOption  = gorun.ReadOption
+
+	DeleteOptions = gorun.DeleteOptions
+	DeleteOption  = gorun.DeleteOption
+)
+
+// ReadNamespace scopes a Read to a single namespace
+func ReadNamespace(ns string) ReadOption {
+	return func(o *ReadOptions) {
+		o.Namespace = ns
+	}
+}
+
+// ReadService scopes a Read to a single service name
+func ReadService(name string) ReadOption {
+	return func(o *ReadOptions) {
+		o.Service = name
+	}
+}
+
+// ReadVersion scopes a Read to a single service version
+func ReadVersion(version string) ReadOption {
+	return func(o *ReadOptions) {
+		o.Version = version
+	}
+}
+
+// DeleteNamespace scopes a Delete to a single namespace
+func DeleteNamespace(ns string) DeleteOption {
+	return func(o *DeleteOptions) {
+		o.Namespace = ns
+	}
+}
+
+// AutoscalePolicy declares how a service should be scaled. A Min of 0
+// enables scale-to-zero.
+type AutoscalePolicy struct {
+	MinReplicas      int
+	MaxReplicas      int
+	TargetCPUPercent int
+	TargetRPS        int
+	Cooldown         time.Duration
+}
+
+// CreateOptions configure Create. Unlike ReadOptions/DeleteOptions this
+// carries manager-specific fields (Builder, Autoscale) that the underlying
+// gorun.Runtime backend never sees directly.
+type CreateOptions struct {
+	Namespace string
+	Instances int
+	Env       []string
+	// Builder selects the registered builder.Builder used to turn this
+	// service's source into an image; empty uses the namespace default set
+	// with builder.SetNamespaceDefault, then builder.DefaultBuilder
+	Builder string
+	// Autoscale, if set, is handed to the manager's autoscaler control loop
+	Autoscale *AutoscalePolicy
+}
+
+// CreateOption sets a CreateOptions value
+type CreateOption func(*CreateOptions)
+
+// CreateNamespace sets the namespace a service is created in
+func CreateNamespace(ns string) CreateOption {
+	return func(o *CreateOptions) {
+		o.Namespace = ns
+	}
+}
+
+// CreateInstances sets the number of instances to start
+func CreateInstances(i int) CreateOption {
+	return func(o *CreateOptions) {
+		o.Instances = i
+	}
+}
+
+// CreateEnv sets environment variables passed to the service
+func CreateEnv(env []string) CreateOption {
+	return func(o *CreateOptions) {
+		o.Env = env
+	}
+}
+
+// CreateBuilder selects the builder used to build this service's source
+func CreateBuilder(name string) CreateOption {
+	return func(o *CreateOptions) {
+		o.Builder = name
+	}
+}
+
+// CreateAutoscale attaches an autoscale policy to the service
+func CreateAutoscale(p *AutoscalePolicy) CreateOption {
+	return func(o *CreateOptions) {
+		o.Autoscale = p
+	}
+}
+
+// Strategy is the rollout strategy Update uses to move a service from its
+// current version to a new one
+type Strategy string
+
+const (
+	// StrategyRecreate stops the old version before starting the new one
+	StrategyRecreate Strategy = "recreate"
+	// StrategyRollingUpdate replaces instances of the old version with the
+	// new one gradually
+	StrategyRollingUpdate Strategy = "rolling_update"
+	// StrategyBlueGreen runs the new version (green) alongside the old one
+	// (blue) until Promote replaces blue with it; no health check gates
+	// the switch
+	StrategyBlueGreen Strategy = "blue_green"
+	// StrategyCanary runs the new version alongside the old one, with the
+	// traffic weight recorded in its metadata, until it's promoted or
+	// aborted; nothing in this repository routes traffic by that weight
+	StrategyCanary Strategy = "canary"
+)
+
+// UpdateOptions configure Update
+type UpdateOptions struct {
+	Namespace string
+	Instances int
+	// Env replaces the environment variables passed to the service, if set
+	Env []string
+	// Autoscale replaces the autoscale policy recorded for the service, if set
+	Autoscale *AutoscalePolicy
+	// Strategy selects how the new version is rolled out; the zero value
+	// behaves as StrategyRecreate
+	Strategy Strategy
+	// CanaryWeight is the percentage of traffic sent to the canary version
+	// when Strategy is StrategyCanary
+	CanaryWeight int
+}
+
+// UpdateOption sets an UpdateOptions value
+type UpdateOption func(*UpdateOptions)
+
+// UpdateNamespace scopes an Update to a single namespace
+func UpdateNamespace(ns string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Namespace = ns
+	}
+}
+
+// UpdateInstances changes the number of running instances
+func UpdateInstances(i int) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Instances = i
+	}
+}
+
+// UpdateEnv replaces the environment variables passed to the service
+func UpdateEnv(env []string) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Env = env
+	}
+}
+
+// UpdateAutoscale replaces the autoscale policy recorded for the service
+func UpdateAutoscale(p *AutoscalePolicy) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Autoscale = p
+	}
+}
+
+// UpdateStrategy selects the rollout strategy
+func UpdateStrategy(s Strategy) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.Strategy = s
+	}
+}
+
+// UpdateCanaryWeight sets the canary traffic split; only meaningful when
+// combined with UpdateStrategy(StrategyCanary)
+func UpdateCanaryWeight(weight int) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.CanaryWeight = weight
+	}
+}
+
+// Runtime manages services on top of a gorun.Runtime backend, layering in
+// namespacing, builds, deployment strategies and autoscaling. manager.New
+// returns a Runtime wrapping whichever gorun.Runtime backend DefaultRuntime
+// points to.
+type Runtime interface {
+	Create(srv *Service, opts ...CreateOption) error
+	Read(opts ...ReadOption) ([]*Service, error)
+	Update(srv *Service, opts ...UpdateOption) error
+	Delete(srv *Service, opts ...DeleteOption) error
+	Start() error
+	Stop() error
+	String() string
+}
+
+// DefaultRuntime is the backend a profile selects (local, kubernetes,
+// nomad, ...). It has no notion of namespacing, builds or deployment
+// strategies itself - that's what manager.New wraps it with.
+var DefaultRuntime gorun.Runtime
+
+// UnpauseFunc is the KEDA-style scale-from-zero hook: calling it for a
+// service with no running instances scales it back up. manager.New sets
+// this to the manager's own Unpause method, which asks the autoscaler to
+// scale the service back to at least one instance. No router in this
+// repository calls it.
+var UnpauseFunc func(namespace, service, version string) error
+
+// Create registers a service with DefaultRuntime. Only the fields
+// DefaultRuntime itself understands (namespace, instance count, env) are
+// forwarded; Builder and Autoscale are handled by the manager when a
+// service is created through it directly rather than through this
+// package-level helper.
+func Create(srv *Service, opts ...CreateOption) error {
+	var options CreateOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return DefaultRuntime.Create(srv,
+		func(o *gorun.CreateOptions) {
+			o.Namespace = options.Namespace
+			o.Instances = options.Instances
+			o.Env = options.Env
+		},
+	)
+}
+
+// Read returns the services matching the given criteria from DefaultRuntime
+func Read(opts ...ReadOption) ([]*Service, error) {
+	return DefaultRuntime.Read(opts...)
+}
+
+// Update updates a service via DefaultRuntime. As with Create, only the
+// fields DefaultRuntime understands are forwarded.
+func Update(srv *Service, opts ...UpdateOption) error {
+	var options UpdateOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return DefaultRuntime.Update(srv,
+		func(o *gorun.UpdateOptions) {
+			o.Namespace = options.Namespace
+			o.Instances = options.Instances
+		},
+	)
+}
+
+// Delete removes a service via DefaultRuntime
+func Delete(srv *Service, opts ...DeleteOption) error {
+	return DefaultRuntime.Delete(srv, opts...)
+}